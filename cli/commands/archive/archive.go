@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resolutionFlag string
+	audioFlag      string
+	subtitleFlag   string
+	outputFlag     string
+
+	listFlag            string
+	continueOnErrorFlag bool
+
+	remoteFlag string
+)
+
+// Cmd archives every episode a season or episode URL resolves to, writing
+// one file per episode and selected audio locale; the tracks are not merged
+// into a single file. See download.Cmd for keeping a single audio track.
+var Cmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive a series from Crunchyroll",
+
+	// ValidArgsFunction suggests series/movie titles matching what was typed
+	// from the Crunchyroll search API, once the user is logged in. The
+	// search only returns titles, so the suggestions are titles, not URLs.
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		titles, err := utils.SearchTitles(toComplete)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return titles, cobra.ShellCompDirectiveNoFileComp
+	},
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		items, err := utils.ResolveItems(args, listFlag)
+		if err != nil {
+			return err
+		}
+
+		if remoteFlag != "" {
+			id, err := utils.SubmitRemoteBatch(remoteFlag, "archive", items, flags())
+			if err != nil {
+				return err
+			}
+			utils.Log.Info("Submitted job %s to %s", id, remoteFlag)
+			return nil
+		}
+
+		return utils.RunBatch(items, listFlag, continueOnErrorFlag, archiveItem)
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&resolutionFlag, "resolution", "r", "best", "The video resolution")
+	Cmd.Flags().StringVarP(&audioFlag, "audio", "a", "", "The audio locale(s), comma separated")
+	Cmd.Flags().StringVarP(&subtitleFlag, "subtitle", "s", "", "The subtitle locale(s), comma separated")
+	Cmd.Flags().StringVarP(&outputFlag, "output", "o", "{season}/{title}.mkv", "The output file; placeholders such as {title} are used literally, not filled in")
+
+	Cmd.Flags().StringVar(&listFlag, "list", "", "Read URLs (one per line, `-` for stdin) instead of passing them as arguments")
+	Cmd.Flags().BoolVar(&continueOnErrorFlag, "continue-on-error", false, "Record failures and allow re-running the same --list to retry only them")
+
+	Cmd.Flags().StringVar(&remoteFlag, "remote", "", "Submit the job to a running `crunchy daemon` at this address instead of archiving inline")
+
+	_ = Cmd.RegisterFlagCompletionFunc("resolution", utils.CompleteStreamMetadata(func(m utils.StreamMetadata) []string { return m.Resolutions }))
+	_ = Cmd.RegisterFlagCompletionFunc("audio", utils.CompleteStreamMetadata(func(m utils.StreamMetadata) []string { return m.Audio }))
+	_ = Cmd.RegisterFlagCompletionFunc("subtitle", utils.CompleteStreamMetadata(func(m utils.StreamMetadata) []string { return m.Subtitle }))
+}
+
+func flags() utils.CommandFlags {
+	return utils.CommandFlags{
+		Resolution: resolutionFlag,
+		Audio:      audioFlag,
+		Subtitle:   subtitleFlag,
+		Output:     outputFlag,
+	}
+}
+
+// archiveItem resolves an item's effective flags (its own overrides falling
+// back to the command-level flags), then resolves and fetches every
+// episode the item's URL points at, one file per requested audio locale
+// (see utils.FetchEpisodeTracks for why tracks aren't muxed into one file).
+// The first episode goes to output, every later one to output suffixed with
+// its index via utils.TrackOutput.
+func archiveItem(item utils.BatchItem) error {
+	f := flags()
+	resolution := f.Override(item, "resolution")
+	audio := f.Override(item, "audio")
+	output := f.Override(item, "output")
+
+	episodes, err := utils.ResolveEpisodes(item.URL)
+	if err != nil {
+		return err
+	}
+
+	utils.Log.Info("Archiving %s (resolution=%s, audio=%s, output=%s)", item.URL, resolution, audio, output)
+	for i, ep := range episodes {
+		dst := output
+		if i > 0 {
+			dst = utils.TrackOutput(output, strconv.Itoa(i))
+		}
+		if err := utils.FetchEpisodeTracks(ep, dst, resolution, audio); err != nil {
+			return fmt.Errorf("%s: %w", item.URL, err)
+		}
+	}
+	return nil
+}
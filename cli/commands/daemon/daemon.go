@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addrFlag        string
+	socketFlag      string
+	dbFlag          string
+	concurrencyFlag int
+	apiKeyFlag      string
+)
+
+// Cmd runs crunchy-cli as a long-lived background process that accepts
+// download/archive jobs over a local HTTP API instead of executing them
+// inline, so it can sit behind a web UI or a scheduler.
+var Cmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a background process which accepts download/archive jobs over a local API",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDaemon(cmd.Context())
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&addrFlag, "addr", "127.0.0.1:7271", "Loopback address to serve the HTTP API on")
+	Cmd.Flags().StringVar(&socketFlag, "socket", "", "Unix socket to serve the HTTP API on instead of --addr")
+	Cmd.Flags().StringVar(&dbFlag, "db", "", "Path to the job queue database (defaults to the config dir)")
+	Cmd.Flags().IntVar(&concurrencyFlag, "concurrency", 2, "Maximum number of jobs running at the same time")
+	Cmd.Flags().StringVar(&apiKeyFlag, "api-key", "", "Require this API key on every request via a Bearer token")
+
+	Cmd.AddCommand(statusCmd)
+}
+
+func runDaemon(ctx context.Context) error {
+	dbPath := dbFlag
+	if dbPath == "" {
+		dir, err := utils.ConfigDir()
+		if err != nil {
+			return err
+		}
+		dbPath = filepath.Join(dir, "daemon.db")
+	}
+
+	q, err := newQueue(dbPath, concurrencyFlag)
+	if err != nil {
+		return err
+	}
+	defer q.close()
+
+	listener, err := listen()
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	w := newWorker(q)
+	go w.run(ctx)
+
+	srv := &http.Server{Handler: newServer(q, apiKeyFlag).handler()}
+	utils.Log.Info("Daemon listening on %s", listener.Addr())
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func listen() (net.Listener, error) {
+	if socketFlag != "" {
+		_ = os.Remove(socketFlag)
+		return net.Listen("unix", socketFlag)
+	}
+	return net.Listen("tcp", addrFlag)
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report whether a crunchy daemon is reachable and how many jobs it holds",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resp, err := http.Get(fmt.Sprintf("http://%s/jobs", addrFlag))
+		if err != nil {
+			return fmt.Errorf("daemon not reachable on %s: %w", addrFlag, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("daemon responded with %s", resp.Status)
+		}
+
+		var jobs []json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+			return fmt.Errorf("reading job list: %w", err)
+		}
+
+		utils.Log.Info("Daemon is running on %s and holds %d job(s)", addrFlag, len(jobs))
+		return nil
+	},
+}
@@ -0,0 +1,177 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+type jobStatus string
+
+const (
+	jobQueued  jobStatus = "queued"
+	jobRunning jobStatus = "running"
+	jobPaused  jobStatus = "paused"
+	jobDone    jobStatus = "done"
+	jobFailed  jobStatus = "failed"
+)
+
+// job is the persisted representation of a single queued download/archive
+// run, including enough progress information to resume partially downloaded
+// segments after a crash.
+type job struct {
+	ID        string          `json:"id"`
+	Request   utils.JobRequest `json:"request"`
+	Status    jobStatus       `json:"status"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	// SegmentsDone tracks already downloaded segment indices, keyed by URL
+	// and episode index (plus audio locale for archive jobs), so a resumed
+	// job does not re-fetch them.
+	SegmentsDone map[string][]int `json:"segments_done,omitempty"`
+
+	logs []string
+}
+
+var jobsBucket = []byte("jobs")
+
+// queue is a persistent, crash-resumable job queue backed by a bbolt
+// database on disk, with a bounded number of jobs running concurrently.
+type queue struct {
+	db          *bolt.DB
+	concurrency int
+	sem         chan struct{}
+	events      chan jobEvent
+}
+
+// jobEvent is sent on queue.events when a job starts, fetches a segment,
+// fails or finishes; pausing, resuming or deleting a job sends nothing.
+// Every `GET /jobs/{id}/logs` stream reads from that one channel, so each
+// event reaches a single stream, and a stream for another job drops it.
+type jobEvent struct {
+	JobID string
+	Line  string
+}
+
+func newQueue(dbPath string, concurrency int) (*queue, error) {
+	db, err := bolt.Open(dbPath, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("opening job database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q := &queue{
+		db:          db,
+		concurrency: concurrency,
+		sem:         make(chan struct{}, concurrency),
+		events:      make(chan jobEvent, 64),
+	}
+
+	// Any job that was `running` when the daemon last stopped was
+	// interrupted mid-flight; requeue it so resumeJob can pick the
+	// partially downloaded segments back up.
+	if err := q.requeueInterrupted(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+func (q *queue) close() error {
+	return q.db.Close()
+}
+
+func (q *queue) requeueInterrupted() error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(jobsBucket)
+		return b.ForEach(func(k, v []byte) error {
+			var j job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return err
+			}
+			if j.Status == jobRunning {
+				j.Status = jobQueued
+				raw, err := json.Marshal(j)
+				if err != nil {
+					return err
+				}
+				return b.Put(k, raw)
+			}
+			return nil
+		})
+	})
+}
+
+func (q *queue) put(j *job) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		raw, err := json.Marshal(j)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(jobsBucket).Put([]byte(j.ID), raw)
+	})
+}
+
+func (q *queue) get(id string) (*job, error) {
+	var j job
+	err := q.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(jobsBucket).Get([]byte(id))
+		if raw == nil {
+			return fmt.Errorf("no job with id %q", id)
+		}
+		return json.Unmarshal(raw, &j)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (q *queue) list() ([]*job, error) {
+	var jobs []*job
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(k, v []byte) error {
+			var j job
+			if err := json.Unmarshal(v, &j); err != nil {
+				return err
+			}
+			jobs = append(jobs, &j)
+			return nil
+		})
+	})
+	return jobs, err
+}
+
+func (q *queue) delete(id string) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Delete([]byte(id))
+	})
+}
+
+func (q *queue) setStatus(id string, status jobStatus) error {
+	j, err := q.get(id)
+	if err != nil {
+		return err
+	}
+	j.Status = status
+	return q.put(j)
+}
+
+func (q *queue) emit(jobID, line string) {
+	select {
+	case q.events <- jobEvent{JobID: jobID, Line: line}:
+	default:
+		// slow/absent subscribers must not block job execution
+	}
+}
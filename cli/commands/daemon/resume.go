@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	crunchyroll "github.com/crunchy-labs/crunchyroll-go/v3"
+)
+
+// resumeJob executes a queued job, picking up from whichever segments are
+// already recorded in j.SegmentsDone so a crash only has to re-fetch what
+// wasn't finished yet. It dispatches on j.Request.Command since archiving
+// keeps every selected audio track as its own file (utils.FetchEpisodeTracks)
+// while downloading keeps a single one, and persists progress after every
+// segment so a mid-job crash loses at most one segment's work.
+func resumeJob(q *queue, j *job) error {
+	if j.SegmentsDone == nil {
+		j.SegmentsDone = map[string][]int{}
+	}
+
+	resolution := j.Request.Flags["resolution"]
+	audio := j.Request.Flags["audio"]
+	output := j.Request.Flags["output"]
+
+	for _, url := range j.Request.URLs {
+		episodes, err := utils.ResolveEpisodes(url)
+		if err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+
+		for i, ep := range episodes {
+			// Each episode gets its own SegmentsDone key and, after the
+			// first, its own output file, so one episode's progress never
+			// counts as another's.
+			key := url + "#" + strconv.Itoa(i)
+			dst := output
+			if i > 0 {
+				dst = utils.TrackOutput(output, strconv.Itoa(i))
+			}
+			if err := resumeEpisode(q, j, key, url, ep, dst, resolution, audio); err != nil {
+				return fmt.Errorf("%s: %w", url, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func resumeEpisode(q *queue, j *job, key, url string, ep *crunchyroll.Episode, output, resolution, audio string) error {
+	formats, err := ep.Streams()
+	if err != nil {
+		return fmt.Errorf("listing streams: %w", err)
+	}
+
+	switch j.Request.Command {
+	case "download":
+		format, err := utils.ResolveFormat(formats, resolution, audio)
+		if err != nil {
+			return err
+		}
+		return resumeFormat(q, j, key, url, format, output)
+
+	case "archive":
+		locales := utils.SplitLocales(audio)
+		if len(locales) == 0 {
+			format, err := utils.ResolveFormat(formats, resolution, "")
+			if err != nil {
+				return err
+			}
+			return resumeFormat(q, j, key, url, format, output)
+		}
+
+		for _, locale := range locales {
+			format, err := utils.ResolveFormat(formats, resolution, locale)
+			if err != nil {
+				return err
+			}
+			// SegmentsDone is keyed per-locale too, so resuming a job with
+			// several audio tracks doesn't mix up one track's progress
+			// with another's.
+			if err := resumeFormat(q, j, key+"#"+locale, url, format, utils.TrackOutput(output, locale)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown job command %q", j.Request.Command)
+	}
+}
+
+// resumeFormat fetches format's segments to output, skipping whatever's
+// already recorded under segmentsKey in j.SegmentsDone and persisting the
+// job after every new segment so a crash loses at most one segment's work.
+// url is only used for the progress log line. When segments are already
+// recorded, output is appended to instead of recreated, so the segments
+// written before the crash stay in the file.
+func resumeFormat(q *queue, j *job, segmentsKey, url string, format *crunchyroll.Format, output string) error {
+	var out *os.File
+	var err error
+	if len(j.SegmentsDone[segmentsKey]) > 0 {
+		out, err = os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	} else {
+		out, err = utils.CreateOutput(output)
+	}
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	done := make(map[int]bool, len(j.SegmentsDone[segmentsKey]))
+	for _, idx := range j.SegmentsDone[segmentsKey] {
+		done[idx] = true
+	}
+
+	return utils.FetchSegments(format, out, done, func(i int) error {
+		q.emit(j.ID, fmt.Sprintf("%s: fetched segment %d", url, i+1))
+		j.SegmentsDone[segmentsKey] = append(j.SegmentsDone[segmentsKey], i)
+		return q.put(j)
+	})
+}
@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	"github.com/google/uuid"
+)
+
+// server exposes the queue over a local HTTP+JSON API, reachable on a unix
+// socket or a loopback port depending on how `crunchy daemon` was started.
+type server struct {
+	queue  *queue
+	apiKey string
+}
+
+func newServer(q *queue, apiKey string) *server {
+	return &server{queue: q, apiKey: apiKey}
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", s.withAuth(s.handleJobs))
+	mux.HandleFunc("/jobs/", s.withAuth(s.handleJob))
+	return mux
+}
+
+func (s *server) withAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey == "" {
+			next(w, r)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		if auth != "Bearer "+s.apiKey {
+			http.Error(w, "invalid or missing API key", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req utils.JobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		j := &job{ID: uuid.NewString(), Request: req, Status: jobQueued}
+		if err := s.queue.put(j); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": j.ID})
+
+	case http.MethodGet:
+		jobs, err := s.queue.list()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(jobs)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJob dispatches the single-job endpoints: GET/DELETE /jobs/{id} and
+// POST /jobs/{id}/pause|resume, plus the GET /jobs/{id}/logs SSE stream.
+func (s *server) handleJob(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	parts := strings.SplitN(rest, "/", 2)
+	id := parts[0]
+	if id == "" {
+		http.Error(w, "missing job id", http.StatusBadRequest)
+		return
+	}
+
+	var action string
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "logs" && r.Method == http.MethodGet:
+		s.streamLogs(w, r, id)
+	case action == "pause" && r.Method == http.MethodPost:
+		s.writeStatus(w, id, jobPaused)
+	case action == "resume" && r.Method == http.MethodPost:
+		s.writeStatus(w, id, jobQueued)
+	case action == "" && r.Method == http.MethodGet:
+		j, err := s.queue.get(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(j)
+	case action == "" && r.Method == http.MethodDelete:
+		if err := s.queue.delete(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func (s *server) writeStatus(w http.ResponseWriter, id string, status jobStatus) {
+	if err := s.queue.setStatus(id, status); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) streamLogs(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case evt := <-s.queue.events:
+			if evt.JobID != id {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", evt.Line)
+			flusher.Flush()
+		}
+	}
+}
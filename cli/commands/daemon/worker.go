@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"context"
+	"time"
+)
+
+// worker repeatedly scans the queue for queued jobs and runs up to
+// `concurrency` of them at once. A pause request made through the API only
+// holds back a job that has not started yet; a running job runs to the end,
+// and its own status updates overwrite the paused status.
+type worker struct {
+	queue *queue
+}
+
+func newWorker(q *queue) *worker {
+	return &worker{queue: q}
+}
+
+func (w *worker) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.pollOnce()
+		}
+	}
+}
+
+func (w *worker) pollOnce() {
+	jobs, err := w.queue.list()
+	if err != nil {
+		return
+	}
+
+	for _, j := range jobs {
+		if j.Status != jobQueued {
+			continue
+		}
+
+		select {
+		case w.queue.sem <- struct{}{}:
+			// Flip the status before handing off to the goroutine: if the
+			// next tick fires before runJob's first line runs, the DB must
+			// already read `running`, or the same job gets dispatched twice.
+			j.Status = jobRunning
+			if err := w.queue.put(j); err != nil {
+				<-w.queue.sem
+				continue
+			}
+			go w.runJob(j)
+		default:
+			return
+		}
+	}
+}
+
+func (w *worker) runJob(j *job) {
+	defer func() { <-w.queue.sem }()
+
+	w.queue.emit(j.ID, "starting "+j.Request.Command)
+
+	// resumeJob downloads/archives each URL in the request, skipping
+	// segments already recorded in j.SegmentsDone so a daemon restart after
+	// a crash continues instead of starting over.
+	if err := resumeJob(w.queue, j); err != nil {
+		j.Status = jobFailed
+		j.Error = err.Error()
+		w.queue.emit(j.ID, "failed: "+err.Error())
+	} else {
+		j.Status = jobDone
+		w.queue.emit(j.ID, "done")
+	}
+	_ = w.queue.put(j)
+}
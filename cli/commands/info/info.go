@@ -0,0 +1,42 @@
+package info
+
+import (
+	"strings"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// Cmd prints the resolutions, audio and subtitle locales available for a
+// season or episode URL (for a season, those of its first episode).
+var Cmd = &cobra.Command{
+	Use:   "info [url]",
+	Short: "Show information about a Crunchyroll season or episode",
+	Args:  cobra.ExactArgs(1),
+
+	// ValidArgsFunction suggests series/movie titles matching what was typed
+	// from the Crunchyroll search API once the user has logged in. The
+	// search only returns titles, so the suggestions are titles, not URLs.
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		titles, err := utils.SearchTitles(toComplete)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return titles, cobra.ShellCompDirectiveNoFileComp
+	},
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		meta, err := utils.StreamMetadataFor(args[0])
+		if err != nil {
+			return err
+		}
+
+		utils.Log.Info("%s: resolutions=%s, audio=%s, subtitle=%s",
+			args[0], strings.Join(meta.Resolutions, ","), strings.Join(meta.Audio, ","), strings.Join(meta.Subtitle, ","))
+		return nil
+	},
+}
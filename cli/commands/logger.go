@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+)
+
+// Logger is a minimal leveled logger that writes straight to stdout/stderr,
+// gated by which levels were enabled when it was created.
+type Logger struct {
+	debug, info, err bool
+}
+
+// NewLogger creates a Logger with the given levels enabled.
+func NewLogger(debug, info, err bool) *Logger {
+	return &Logger{debug: debug, info: info, err: err}
+}
+
+func (l *Logger) Debug(format string, args ...interface{}) {
+	if l.debug {
+		fmt.Fprintf(os.Stdout, "[DEBUG] "+format+"\n", args...)
+	}
+}
+
+func (l *Logger) Info(format string, args ...interface{}) {
+	if l.info {
+		fmt.Fprintf(os.Stdout, format+"\n", args...)
+	}
+}
+
+func (l *Logger) Err(format string, args ...interface{}) {
+	if l.err {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+func (l *Logger) IsDev() bool {
+	return l.debug
+}
@@ -0,0 +1,67 @@
+package login
+
+import (
+	"fmt"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	crunchyroll "github.com/crunchy-labs/crunchyroll-go/v3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionIDFlag string
+	emailFlag     string
+	passwordFlag  string
+)
+
+// Cmd logs in and persists the resulting session via whichever
+// `--credentials-backend` is active, so subsequent commands don't need an
+// explicit login.
+var Cmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Crunchyroll and store the session",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backend, err := utils.CredentialsBackendFor(utils.CredentialsBackendFlag)
+		if err != nil {
+			return err
+		}
+
+		client, err := login()
+		if err != nil {
+			return err
+		}
+
+		creds := utils.Credentials{SessionID: client.RefreshToken, Email: emailFlag, Password: passwordFlag}
+		if err := backend.Save(creds); err != nil {
+			return fmt.Errorf("storing credentials: %w", err)
+		}
+
+		utils.Client = client
+		utils.Log.Info("Logged in, session stored via --credentials-backend=%s", utils.CredentialsBackendFlag)
+		return nil
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&sessionIDFlag, "session-id", "", "Log in with an existing refresh token instead of email/password")
+	Cmd.Flags().StringVarP(&emailFlag, "email", "e", "", "Crunchyroll account email")
+	Cmd.Flags().StringVar(&passwordFlag, "password", "", "Crunchyroll account password")
+}
+
+// login authenticates with whichever of --session-id or --email/--password
+// was given, routed through the same --proxy/--proxy-rules/--proxy-auth and
+// --useragent RootCmd resolved for every other command.
+func login() (*crunchyroll.Crunchyroll, error) {
+	httpClient := utils.NewHTTPClient(utils.ActiveProxyConfig, utils.ActiveUserAgent)
+
+	if sessionIDFlag != "" {
+		return crunchyroll.LoginWithSessionID(sessionIDFlag, crunchyroll.US, httpClient)
+	}
+
+	if emailFlag == "" || passwordFlag == "" {
+		return nil, fmt.Errorf("either --session-id or both --email and --password are required")
+	}
+
+	return crunchyroll.LoginWithCredentials(emailFlag, passwordFlag, crunchyroll.US, httpClient)
+}
@@ -0,0 +1,162 @@
+package shell
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+)
+
+func (s *session) search(query string) {
+	titles, err := utils.SearchTitles(query)
+	if err != nil {
+		utils.Log.Err("Searching failed: %v", err)
+		return
+	}
+	if len(titles) == 0 {
+		utils.Log.Info("No results for `%s`", query)
+		return
+	}
+	for _, title := range titles {
+		utils.Log.Info(title)
+	}
+}
+
+// cd changes the current browsing location to a Crunchyroll URL. `search`
+// only returns titles, not URLs (the Crunchyroll API doesn't hand those
+// back from a search), so for now a URL has to be pasted in directly rather
+// than picked from `search` output by name.
+func (s *session) cd(args []string) {
+	if len(args) == 0 {
+		s.Cwd = ""
+		return
+	}
+
+	target := strings.Join(args, " ")
+	if target == ".." {
+		s.Cwd = ""
+		return
+	}
+
+	s.Cwd = target
+}
+
+// ls resolves the current location against the Crunchyroll API and lists
+// its episodes.
+func (s *session) ls() {
+	if s.Cwd == "" {
+		utils.Log.Err("Not in a location yet, `cd <url>` into one first")
+		return
+	}
+
+	episodes, err := utils.ResolveEpisodes(s.Cwd)
+	if err != nil {
+		utils.Log.Err("Resolving `%s` failed: %v", s.Cwd, err)
+		return
+	}
+
+	for i, ep := range episodes {
+		utils.Log.Info("%d: %s", i, ep.Title)
+	}
+}
+
+// info shows the available resolutions/audio/subtitle locales for the
+// current location or an explicitly given URL.
+func (s *session) info(args []string) {
+	target := s.Cwd
+	if len(args) > 0 {
+		target = strings.Join(args, " ")
+	}
+	if target == "" {
+		utils.Log.Err("Not in a location yet, `cd <url>` into one first or pass `info <url>`")
+		return
+	}
+
+	meta, err := utils.StreamMetadataFor(target)
+	if err != nil {
+		utils.Log.Err("Resolving `%s` failed: %v", target, err)
+		return
+	}
+
+	utils.Log.Info("%s: resolutions=%s, audio=%s, subtitle=%s",
+		target, strings.Join(meta.Resolutions, ","), strings.Join(meta.Audio, ","), strings.Join(meta.Subtitle, ","))
+}
+
+// queueItems adds a URL to the download/archive queue: either one passed
+// explicitly or the current location.
+func (s *session) queueItems(args []string) {
+	item := s.Cwd
+	if len(args) > 0 {
+		item = strings.Join(args, " ")
+	}
+	if item == "" {
+		utils.Log.Err("`queue` requires a URL, or a current location set via `cd`")
+		return
+	}
+
+	s.Queue = append(s.Queue, item)
+	utils.Log.Info("Queued `%s` (%d item(s) in queue)", item, len(s.Queue))
+}
+
+// runQueued resolves and fetches every queued URL, via the same
+// utils.FetchEpisode/FetchEpisodeTracks helpers download.Cmd/archive.Cmd
+// use, honoring whatever resolution/audio/output was set with `set`.
+func (s *session) runQueued(mode string) {
+	if len(s.Queue) == 0 {
+		utils.Log.Info("Queue is empty")
+		return
+	}
+
+	resolution := s.Settings["resolution"]
+	audio := s.Settings["audio"]
+	output := s.Settings["output"]
+	if output == "" {
+		output = mode + ".mp4"
+	}
+
+	for _, url := range s.Queue {
+		episodes, err := utils.ResolveEpisodes(url)
+		if err != nil {
+			utils.Log.Err("%s: %v", url, err)
+			continue
+		}
+
+		for i, ep := range episodes {
+			dst := output
+			if i > 0 {
+				dst = utils.TrackOutput(output, strconv.Itoa(i))
+			}
+
+			var err error
+			switch mode {
+			case "archive":
+				err = utils.FetchEpisodeTracks(ep, dst, resolution, audio)
+			default:
+				err = utils.FetchEpisode(ep, dst, resolution, audio)
+			}
+			if err != nil {
+				utils.Log.Err("%s: %s: %v", url, ep.Title, err)
+				continue
+			}
+			utils.Log.Info("%s %s -> %s", mode, ep.Title, dst)
+		}
+	}
+
+	s.Queue = s.Queue[:0]
+}
+
+func (s *session) set(args []string) {
+	if len(args) < 2 {
+		utils.Log.Err("usage: set <resolution|audio|subtitle|output> <value>")
+		return
+	}
+
+	key, value := args[0], strings.Join(args[1:], " ")
+	switch key {
+	case "resolution", "audio", "subtitle", "output":
+		s.Settings[key] = value
+		utils.Log.Info("%s = %s", key, value)
+	default:
+		utils.Log.Err("unknown setting `%s`", key)
+	}
+}
@@ -0,0 +1,182 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crunchy-labs/crunchy-cli/utils"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+)
+
+// Cmd starts an interactive REPL to browse and queue Crunchyroll content
+// without having to compose URLs by hand.
+var Cmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Start an interactive shell to browse and download Crunchyroll content",
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := loadSession()
+		if err != nil {
+			return fmt.Errorf("loading shell session: %w", err)
+		}
+		defer s.save()
+
+		return s.run()
+	},
+}
+
+// session holds everything that should survive between `crunchy shell`
+// invocations.
+type session struct {
+	Cwd      string            `json:"cwd"`
+	Queue    []string          `json:"queue"`
+	Settings map[string]string `json:"settings"`
+
+	path string
+	line *liner.State
+}
+
+func sessionPath() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "shell_session.json"), nil
+}
+
+func loadSession() (*session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		Settings: map[string]string{},
+		path:     path,
+	}
+
+	if raw, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(raw, s); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *session) save() error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, raw, 0o600)
+}
+
+func (s *session) run() error {
+	s.line = liner.NewLiner()
+	defer s.line.Close()
+
+	s.line.SetCompleter(s.complete)
+
+	utils.Log.Info("Type `help` for a list of commands, `exit` to leave the shell")
+
+	for {
+		input, err := s.line.Prompt(s.prompt())
+		if err != nil {
+			if err == liner.ErrPromptAborted || err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		s.line.AppendHistory(input)
+
+		if exit := s.dispatch(input); exit {
+			return nil
+		}
+	}
+}
+
+func (s *session) prompt() string {
+	if s.Cwd == "" {
+		return "crunchy> "
+	}
+	return fmt.Sprintf("crunchy %s> ", s.Cwd)
+}
+
+func (s *session) dispatch(input string) (exit bool) {
+	fields := strings.Fields(input)
+	cmd, rest := fields[0], fields[1:]
+
+	switch cmd {
+	case "exit", "quit":
+		return true
+	case "help":
+		s.printHelp()
+	case "search":
+		s.search(strings.Join(rest, " "))
+	case "cd":
+		s.cd(rest)
+	case "ls":
+		s.ls()
+	case "info":
+		s.info(rest)
+	case "queue":
+		s.queueItems(rest)
+	case "download", "archive":
+		s.runQueued(cmd)
+	case "set":
+		s.set(rest)
+	default:
+		utils.Log.Err("Unknown command `%s`, type `help` for a list of commands", cmd)
+	}
+	return false
+}
+
+func (s *session) printHelp() {
+	for _, line := range []string{
+		"search <query>             search the Crunchyroll catalog for titles",
+		"cd <url>                   change the current browsing location to an episode/season URL",
+		"ls                         list the episodes at the current location",
+		"info [url]                 show resolutions/audio/subtitle available at the current location or an url",
+		"queue [url]                add the current location (or url) to the download queue",
+		"download | archive         download/archive everything in the queue",
+		"set <key> <value>          change a session default (resolution, audio, subtitle, output)",
+		"exit | quit                leave the shell",
+	} {
+		utils.Log.Info(line)
+	}
+}
+
+// complete resolves tab completions for `search` against the Crunchyroll
+// search API. `cd`/`queue`/`info` take URLs, which search results don't
+// provide (Search only returns titles), so they aren't completed here.
+func (s *session) complete(line string) []string {
+	if utils.Client == nil {
+		return nil
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "search" {
+		return nil
+	}
+
+	query := strings.Join(fields[1:], " ")
+	results, err := utils.SearchTitles(query)
+	if err != nil {
+		return nil
+	}
+	completions := make([]string, 0, len(results))
+	for _, r := range results {
+		completions = append(completions, "search "+r)
+	}
+	return completions
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands/archive"
+	"github.com/crunchy-labs/crunchy-cli/cli/commands/completion"
+	"github.com/crunchy-labs/crunchy-cli/cli/commands/daemon"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands/download"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands/info"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands/login"
+	"github.com/crunchy-labs/crunchy-cli/cli/commands/shell"
 	"github.com/crunchy-labs/crunchy-cli/cli/commands/update"
 	"github.com/crunchy-labs/crunchy-cli/utils"
 	"github.com/spf13/cobra"
@@ -20,9 +23,13 @@ var (
 	quietFlag   bool
 	verboseFlag bool
 
-	proxyFlag string
+	proxyFlags     []string
+	proxyRulesFlag string
+	proxyAuthFlag  string
 
 	useragentFlag string
+
+	credentialsBackendFlag string
 )
 
 var RootCmd = &cobra.Command{
@@ -42,7 +49,15 @@ var RootCmd = &cobra.Command{
 
 		utils.Log.Debug("Executing `%s` command with %d arg(s)", cmd.Name(), len(args))
 
-		utils.Client, err = utils.CreateOrDefaultClient(proxyFlag, useragentFlag)
+		proxyCfg, err := utils.NewProxyConfig(proxyFlags, proxyRulesFlag, proxyAuthFlag)
+		if err != nil {
+			return err
+		}
+		utils.ActiveProxyConfig = proxyCfg
+		utils.ActiveUserAgent = useragentFlag
+
+		utils.CredentialsBackendFlag = credentialsBackendFlag
+		utils.Client, err = utils.CreateOrDefaultClient(proxyCfg, useragentFlag)
 		return
 	},
 }
@@ -51,14 +66,21 @@ func init() {
 	RootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Disable all output")
 	RootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "Adds debug messages to the normal output")
 
-	RootCmd.PersistentFlags().StringVarP(&proxyFlag, "proxy", "p", "", "Proxy to use")
+	RootCmd.PersistentFlags().StringArrayVarP(&proxyFlags, "proxy", "p", nil, "Proxy to use (http(s):// or socks5(h)://), can be given multiple times")
+	RootCmd.PersistentFlags().StringVar(&proxyRulesFlag, "proxy-rules", "", "File mapping host globs (e.g. `*.crunchyroll.com`) to specific proxies")
+	RootCmd.PersistentFlags().StringVar(&proxyAuthFlag, "proxy-auth", "", "`user:pass` to use for every proxy that doesn't already carry credentials")
 
 	RootCmd.PersistentFlags().StringVar(&useragentFlag, "useragent", fmt.Sprintf("crunchy-cli/%s", utils.Version), "Useragent to do all request with")
 
+	RootCmd.PersistentFlags().StringVar(&credentialsBackendFlag, "credentials-backend", "file", "Where to load/store the login session: file, netrc, keyring or env")
+
 	RootCmd.AddCommand(archive.Cmd)
+	RootCmd.AddCommand(completion.Cmd)
+	RootCmd.AddCommand(daemon.Cmd)
 	RootCmd.AddCommand(download.Cmd)
 	RootCmd.AddCommand(info.Cmd)
 	RootCmd.AddCommand(login.Cmd)
+	RootCmd.AddCommand(shell.Cmd)
 	RootCmd.AddCommand(update.Cmd)
 
 	utils.Log = commands.NewLogger(false, true, true)
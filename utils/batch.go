@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// BatchItem is a single line from a `--list` file passed to download/archive:
+// a URL plus optional per-item flag overrides that win over the
+// command-level flags for that item only.
+type BatchItem struct {
+	URL   string
+	Flags map[string]string
+}
+
+// ParseBatchList reads a `--list` file (or stdin), skipping blank lines and
+// lines starting with `#`. Each remaining line is a URL, optionally followed
+// by `key=value` overrides, e.g.:
+//
+//	https://crunchyroll.com/watch/abc resolution=1080p subtitle=de-DE
+func ParseBatchList(r io.Reader) ([]BatchItem, error) {
+	var items []BatchItem
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		item := BatchItem{URL: fields[0], Flags: map[string]string{}}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid override %q on line %q, expected key=value", field, line)
+			}
+			item.Flags[key] = value
+		}
+
+		items = append(items, item)
+	}
+
+	return items, scanner.Err()
+}
+
+// ReadBatchList opens a `--list` file and parses it, treating the path `-`
+// as stdin so `download`/`archive` can share the same flag handling.
+func ReadBatchList(path string) ([]BatchItem, error) {
+	if path == "-" {
+		return ParseBatchList(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening --list file: %w", err)
+	}
+	defer f.Close()
+
+	return ParseBatchList(f)
+}
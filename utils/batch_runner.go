@@ -0,0 +1,147 @@
+package utils
+
+import "github.com/spf13/cobra"
+
+// CommandFlags is the set of command-level flags download/archive both
+// expose (--resolution, --audio, --subtitle, --output), used as the
+// fallback when a --list item doesn't override them itself.
+type CommandFlags struct {
+	Resolution string
+	Audio      string
+	Subtitle   string
+	Output     string
+}
+
+// Override resolves the effective value of key for item, falling back to
+// the command-level flags when the item doesn't carry its own override.
+func (f CommandFlags) Override(item BatchItem, key string) string {
+	if value, ok := item.Flags[key]; ok {
+		return value
+	}
+
+	switch key {
+	case "resolution":
+		return f.Resolution
+	case "audio":
+		return f.Audio
+	case "subtitle":
+		return f.Subtitle
+	case "output":
+		return f.Output
+	default:
+		return ""
+	}
+}
+
+func (f CommandFlags) asMap() map[string]string {
+	return map[string]string{
+		"resolution": f.Resolution,
+		"audio":      f.Audio,
+		"subtitle":   f.Subtitle,
+		"output":     f.Output,
+	}
+}
+
+// ResolveItems merges the items passed as positional args with whatever a
+// `--list` file contributes, in the order they should be processed. Shared
+// by download and archive.
+func ResolveItems(args []string, listPath string) ([]BatchItem, error) {
+	items := make([]BatchItem, 0, len(args))
+	for _, arg := range args {
+		items = append(items, BatchItem{URL: arg, Flags: map[string]string{}})
+	}
+
+	if listPath == "" {
+		return items, nil
+	}
+
+	listed, err := ReadBatchList(listPath)
+	if err != nil {
+		return nil, err
+	}
+	return append(items, listed...), nil
+}
+
+// SubmitRemoteBatch submits every item as a single JobRequest to a running
+// daemon, returning the assigned job id. Only the items' URLs are sent: the
+// job applies the command-level flags to every URL, and per-item overrides
+// from a --list file are not passed on.
+func SubmitRemoteBatch(addr, command string, items []BatchItem, flags CommandFlags) (string, error) {
+	urls := make([]string, len(items))
+	for i, item := range items {
+		urls[i] = item.URL
+	}
+
+	return SubmitRemoteJob(addr, "", JobRequest{
+		Command: command,
+		URLs:    urls,
+		Flags:   flags.asMap(),
+	})
+}
+
+// RunBatch runs run for every item, honouring --continue-on-error by
+// persisting progress to a batch state file keyed off listPath so a re-run
+// of the same list skips what already succeeded. Shared by download and
+// archive.
+func RunBatch(items []BatchItem, listPath string, continueOnError bool, run func(BatchItem) error) error {
+	var state *BatchState
+	if listPath != "" && continueOnError {
+		path, err := BatchStatePath(listPath)
+		if err != nil {
+			return err
+		}
+		state, err = LoadBatchState(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range items {
+		if state != nil && state.ShouldSkip(item) {
+			Log.Info("Skipping %s (already completed)", item.URL)
+			continue
+		}
+
+		if err := run(item); err != nil {
+			Log.Err("%s failed: %v", item.URL, err)
+			if state != nil {
+				state.MarkFailed(item)
+				if err := state.Save(); err != nil {
+					return err
+				}
+				if continueOnError {
+					continue
+				}
+			}
+			return err
+		}
+
+		if state != nil {
+			state.MarkDone(item)
+			if err := state.Save(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CompleteStreamMetadata builds a flag completion function that resolves the
+// stream metadata of the URL already typed as the command's first argument,
+// then picks one field out of it (resolutions, audio locales, subtitles).
+// Shared by download and archive's --resolution/--audio/--subtitle
+// completions.
+func CompleteStreamMetadata(field func(StreamMetadata) []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		meta, err := StreamMetadataFor(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return field(meta), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BatchState records which items of a `--list` invocation already finished
+// so re-running the same list with `--continue-on-error` only retries what
+// previously failed.
+type BatchState struct {
+	path    string
+	Done    map[string]bool `json:"done"`
+	Failed  map[string]bool `json:"failed"`
+}
+
+// BatchStatePath derives a stable state file path from the list file itself,
+// so the same `--list` invocation always resumes the same state.
+func BatchStatePath(listPath string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum([]byte(listPath))
+	return filepath.Join(dir, fmt.Sprintf("batch-%x.json", sum)), nil
+}
+
+func LoadBatchState(path string) (*BatchState, error) {
+	s := &BatchState{path: path, Done: map[string]bool{}, Failed: map[string]bool{}}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("parsing batch state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *BatchState) Save() error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, raw, 0o600)
+}
+
+// ShouldSkip reports whether an item already completed successfully on a
+// previous run of the same list.
+func (s *BatchState) ShouldSkip(item BatchItem) bool {
+	return s.Done[item.URL]
+}
+
+func (s *BatchState) MarkDone(item BatchItem) {
+	delete(s.Failed, item.URL)
+	s.Done[item.URL] = true
+}
+
+func (s *BatchState) MarkFailed(item BatchItem) {
+	s.Failed[item.URL] = true
+}
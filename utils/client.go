@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	crunchyroll "github.com/crunchy-labs/crunchyroll-go/v3"
+)
+
+// Client is the Crunchyroll client subcommands operate on, set up by
+// RootCmd.PersistentPreRunE via CreateOrDefaultClient.
+var Client *crunchyroll.Crunchyroll
+
+// CredentialsBackendFlag is the backend selected via
+// `--credentials-backend`, read by CreateOrDefaultClient.
+var CredentialsBackendFlag string
+
+// ActiveProxyConfig and ActiveUserAgent mirror RootCmd's resolved
+// `--proxy`/`--proxy-rules`/`--proxy-auth`/`--useragent` flags, set in
+// PersistentPreRunE. Subcommands that build their own http.Client outside of
+// CreateOrDefaultClient (e.g. `crunchy login`, which runs before a Client
+// exists) use these so they still honor the same flags.
+var (
+	ActiveProxyConfig *ProxyConfig
+	ActiveUserAgent   string
+)
+
+// CreateOrDefaultClient builds a Crunchyroll client, logging in with
+// whatever credentials the selected `--credentials-backend` provides so
+// subcommands don't require an explicit `crunchy login` on every run, and
+// routes its requests through proxyCfg (nil for a direct connection).
+func CreateOrDefaultClient(proxyCfg *ProxyConfig, useragent string) (*crunchyroll.Crunchyroll, error) {
+	backend, err := CredentialsBackendFor(CredentialsBackendFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading credentials: %w", err)
+	}
+	if creds.empty() {
+		// No stored credentials yet; subcommands that need a client will
+		// fail with a clear "not logged in" error instead of panicking on
+		// a nil *crunchyroll.Crunchyroll.
+		return nil, nil
+	}
+
+	httpClient := NewHTTPClient(proxyCfg, useragent)
+
+	if creds.SessionID != "" {
+		return crunchyroll.LoginWithSessionID(creds.SessionID, crunchyroll.US, httpClient)
+	}
+	return crunchyroll.LoginWithCredentials(creds.Email, creds.Password, crunchyroll.US, httpClient)
+}
+
+// NewHTTPClient builds an *http.Client that routes through proxyCfg (nil for
+// a direct connection) and stamps every request with useragent. Shared by
+// CreateOrDefaultClient and `crunchy login` so both honor
+// --proxy/--proxy-rules/--proxy-auth.
+func NewHTTPClient(proxyCfg *ProxyConfig, useragent string) *http.Client {
+	var transport http.RoundTripper = transportFor(proxyCfg)
+	if useragent != "" {
+		transport = &useragentRoundTripper{rt: transport, useragent: useragent}
+	}
+	return &http.Client{Transport: transport}
+}
+
+type useragentRoundTripper struct {
+	rt        http.RoundTripper
+	useragent string
+}
+
+func (u *useragentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", u.useragent)
+	return u.rt.RoundTrip(req)
+}
+
+// transportFor builds an http.Transport whose Proxy func consults the
+// per-host rules in proxyCfg. net/http dials http(s):// and socks5(h)://
+// proxy URLs returned from Proxy natively, so nothing else is needed here;
+// a custom DialContext that re-resolved the proxy for its own address would
+// try to tunnel a SOCKS5 proxy through itself.
+func transportFor(proxyCfg *ProxyConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyCfg != nil {
+		transport.Proxy = proxyCfg.For
+	}
+	return transport
+}
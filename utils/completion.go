@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"fmt"
+
+	crunchyroll "github.com/crunchy-labs/crunchyroll-go/v3"
+)
+
+// searchLimit caps how many series/movies Search asks Crunchyroll for when
+// used to build shell completions.
+const searchLimit = 10
+
+// SearchTitles queries the Crunchyroll search API for series/movie titles
+// matching query. It backs both the `shell` REPL's completion and the
+// dynamic ValidArgsFunction on download/archive/info.
+func SearchTitles(query string) ([]string, error) {
+	if query == "" {
+		return nil, nil
+	}
+	if Client == nil {
+		return nil, fmt.Errorf("not logged in")
+	}
+
+	series, movies, err := Client.Search(query, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, 0, len(series)+len(movies))
+	for _, s := range series {
+		titles = append(titles, s.Title)
+	}
+	for _, m := range movies {
+		titles = append(titles, m.Title)
+	}
+	return titles, nil
+}
+
+// ResolveEpisodes resolves url to the episodes it refers to: a season/episode
+// URL resolves to just that season's/episode's entries, while a series URL
+// is rejected with an error. Shared by every place that needs
+// to turn a URL into something it can pull formats from (stream metadata
+// completion, download, archive).
+func ResolveEpisodes(url string) ([]*crunchyroll.Episode, error) {
+	if Client == nil {
+		return nil, fmt.Errorf("not logged in")
+	}
+
+	series, episodes, err := Client.ParseUrl(url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(episodes) > 0:
+		return episodes, nil
+	case series != nil:
+		return nil, fmt.Errorf("%s resolved to a series; pass a season or episode URL instead", url)
+	default:
+		return nil, fmt.Errorf("%s did not resolve to a series or episode", url)
+	}
+}
+
+// StreamMetadata is the subset of a resolved stream's metadata needed to
+// complete --resolution/--audio/--subtitle once the user has typed a URL.
+type StreamMetadata struct {
+	Resolutions []string
+	Audio       []string
+	Subtitle    []string
+}
+
+// StreamMetadataFor fetches the available resolutions/audio/subtitle locales
+// for the episode at url, used to complete
+// --resolution/--audio/--subtitle once the preceding URL argument is known.
+func StreamMetadataFor(url string) (StreamMetadata, error) {
+	episodes, err := ResolveEpisodes(url)
+	if err != nil {
+		return StreamMetadata{}, err
+	}
+
+	formats, err := episodes[0].Streams()
+	if err != nil {
+		return StreamMetadata{}, err
+	}
+	return streamMetadataFromFormats(formats), nil
+}
+
+func streamMetadataFromFormats(formats []*crunchyroll.Format) StreamMetadata {
+	meta := StreamMetadata{}
+	for _, f := range formats {
+		if f.Video != nil {
+			meta.Resolutions = append(meta.Resolutions, f.Video.Resolution)
+		}
+		meta.Audio = append(meta.Audio, string(f.AudioLocale))
+		for _, sub := range f.Subtitles {
+			meta.Subtitle = append(meta.Subtitle, string(sub.Locale))
+		}
+	}
+	return meta
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir returns the directory crunchy-cli stores persistent state in
+// (login tokens, shell sessions, daemon queues, ...), creating it if it
+// does not exist yet.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "crunchy-cli")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
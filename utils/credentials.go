@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Credentials is whatever is needed to (re-)authenticate against
+// Crunchyroll: either a session id left over from a previous login, or an
+// email/password pair.
+type Credentials struct {
+	SessionID string
+	Email     string
+	Password  string
+}
+
+func (c Credentials) empty() bool {
+	return c.SessionID == "" && c.Email == ""
+}
+
+// CredentialsBackend stores and retrieves Credentials so subcommands don't
+// have to log in again on every invocation.
+type CredentialsBackend interface {
+	Load() (Credentials, error)
+	Save(Credentials) error
+}
+
+const keyringService = "crunchy-cli"
+
+// CredentialsBackendFor resolves the backend named by `--credentials-backend`.
+func CredentialsBackendFor(name string) (CredentialsBackend, error) {
+	switch name {
+	case "", "file":
+		return fileBackend{}, nil
+	case "netrc":
+		return netrcBackend{}, nil
+	case "keyring":
+		return keyringBackend{}, nil
+	case "env":
+		return envBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials backend %q", name)
+	}
+}
+
+// fileBackend is the legacy default: a plaintext session file in the config
+// dir, kept for backwards compatibility with existing setups.
+type fileBackend struct{}
+
+func credentialsFilePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session"), nil
+}
+
+func (fileBackend) Load() (Credentials, error) {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Credentials{}, nil
+	} else if err != nil {
+		return Credentials{}, err
+	}
+
+	return Credentials{SessionID: strings.TrimSpace(string(raw))}, nil
+}
+
+func (fileBackend) Save(c Credentials) error {
+	path, err := credentialsFilePath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(c.SessionID), 0o600)
+}
+
+// netrcBackend reads email/password from ~/.netrc, under the
+// `crunchyroll.com` machine entry.
+type netrcBackend struct{}
+
+func (netrcBackend) Load() (Credentials, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	f, err := os.Open(filepath.Join(home, ".netrc"))
+	if os.IsNotExist(err) {
+		return Credentials{}, nil
+	} else if err != nil {
+		return Credentials{}, err
+	}
+	defer f.Close()
+
+	var inMachine bool
+	var creds Credentials
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for i := 0; i < len(fields)-1; i++ {
+			switch fields[i] {
+			case "machine":
+				inMachine = fields[i+1] == "crunchyroll.com"
+			case "login":
+				if inMachine {
+					creds.Email = fields[i+1]
+				}
+			case "password":
+				if inMachine {
+					creds.Password = fields[i+1]
+				}
+			}
+		}
+	}
+
+	return creds, scanner.Err()
+}
+
+func (netrcBackend) Save(Credentials) error {
+	return fmt.Errorf("the netrc credentials backend is read-only, edit ~/.netrc directly")
+}
+
+// keyringBackend stores the session id in the OS keychain via go-keyring
+// (macOS Keychain, Windows Credential Manager, libsecret on Linux).
+type keyringBackend struct{}
+
+func (keyringBackend) Load() (Credentials, error) {
+	sessionID, err := keyring.Get(keyringService, "session")
+	if err == keyring.ErrNotFound {
+		return Credentials{}, nil
+	} else if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{SessionID: sessionID}, nil
+}
+
+func (keyringBackend) Save(c Credentials) error {
+	return keyring.Set(keyringService, "session", c.SessionID)
+}
+
+// envBackend reads CRUNCHY_SESSION_ID / CRUNCHY_EMAIL / CRUNCHY_PASSWORD and
+// never persists anything, which is why it's the safest choice for CI.
+type envBackend struct{}
+
+func (envBackend) Load() (Credentials, error) {
+	return Credentials{
+		SessionID: os.Getenv("CRUNCHY_SESSION_ID"),
+		Email:     os.Getenv("CRUNCHY_EMAIL"),
+		Password:  os.Getenv("CRUNCHY_PASSWORD"),
+	}, nil
+}
+
+func (envBackend) Save(Credentials) error {
+	return nil
+}
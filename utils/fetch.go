@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	crunchyroll "github.com/crunchy-labs/crunchyroll-go/v3"
+)
+
+// ResolveFormat picks the format from formats whose video resolution and
+// audio locale match resolution/audio (an empty audio matches any locale).
+// When resolution is "" or "best" it picks the first format with a matching
+// audio locale, since Crunchyroll returns formats best-first.
+func ResolveFormat(formats []*crunchyroll.Format, resolution, audio string) (*crunchyroll.Format, error) {
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no formats available")
+	}
+
+	if resolution == "" || resolution == "best" {
+		for _, f := range formats {
+			if audio == "" || string(f.AudioLocale) == audio {
+				return f, nil
+			}
+		}
+		return nil, fmt.Errorf("no format matches audio=%s", audio)
+	}
+
+	for _, f := range formats {
+		if f.Video == nil || f.Video.Resolution != resolution {
+			continue
+		}
+		if audio == "" || string(f.AudioLocale) == audio {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no format matches resolution=%s audio=%s", resolution, audio)
+}
+
+// FetchSegments downloads every segment of f to dst in order, skipping
+// indexes already present in done, and calls onSegment after each one
+// completes so callers (the daemon) can persist resumable progress.
+func FetchSegments(f *crunchyroll.Format, dst io.Writer, done map[int]bool, onSegment func(index int) error) error {
+	segments, err := f.Segments()
+	if err != nil {
+		return fmt.Errorf("listing segments: %w", err)
+	}
+
+	for i, seg := range segments {
+		if done[i] {
+			continue
+		}
+		if _, err := seg.Download(dst); err != nil {
+			return fmt.Errorf("segment %d: %w", i, err)
+		}
+		if onSegment != nil {
+			if err := onSegment(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FetchEpisode resolves ep's formats, picks the one matching
+// resolution/audio and writes it to output, creating output's parent
+// directory if needed.
+func FetchEpisode(ep *crunchyroll.Episode, output, resolution, audio string) error {
+	formats, err := ep.Streams()
+	if err != nil {
+		return fmt.Errorf("listing streams: %w", err)
+	}
+
+	format, err := ResolveFormat(formats, resolution, audio)
+	if err != nil {
+		return err
+	}
+
+	out, err := CreateOutput(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return FetchSegments(format, out, nil, nil)
+}
+
+// FetchEpisodeTracks is FetchEpisode for archive's `--audio`, which accepts
+// a comma-separated list of locales to keep instead of a single one. Each
+// matching audio track is written next to output, suffixed with its locale
+// via TrackOutput, since muxing every track into a single container isn't
+// implemented here.
+func FetchEpisodeTracks(ep *crunchyroll.Episode, output, resolution, audio string) error {
+	formats, err := ep.Streams()
+	if err != nil {
+		return fmt.Errorf("listing streams: %w", err)
+	}
+
+	locales := SplitLocales(audio)
+	if len(locales) == 0 {
+		format, err := ResolveFormat(formats, resolution, "")
+		if err != nil {
+			return err
+		}
+		return writeFormat(format, output)
+	}
+
+	for _, locale := range locales {
+		format, err := ResolveFormat(formats, resolution, locale)
+		if err != nil {
+			return err
+		}
+		if err := writeFormat(format, TrackOutput(output, locale)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFormat(format *crunchyroll.Format, output string) error {
+	out, err := CreateOutput(output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return FetchSegments(format, out, nil, nil)
+}
+
+// TrackOutput suffixes output with locale, right before its extension, so
+// archive's one-file-per-audio-track output doesn't collide across locales.
+func TrackOutput(output, locale string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "." + locale + ext
+}
+
+// SplitLocales splits a --audio/--subtitle flag's comma-separated locale
+// list, trimming whitespace and dropping empty entries.
+func SplitLocales(flag string) []string {
+	if flag == "" {
+		return nil
+	}
+
+	var locales []string
+	for _, l := range strings.Split(flag, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			locales = append(locales, l)
+		}
+	}
+	return locales
+}
+
+// CreateOutput creates output, making its parent directory if needed.
+func CreateOutput(output string) (*os.File, error) {
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return os.Create(output)
+}
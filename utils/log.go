@@ -0,0 +1,18 @@
+package utils
+
+// Logger is the interface RootCmd and every subcommand log through. It is
+// swapped out for a quieter/louder implementation depending on the
+// `--quiet`/`--verbose` flags.
+type Logger interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Err(format string, args ...interface{})
+	IsDev() bool
+}
+
+// Log is the logger currently in use, set up when the cli package is
+// initialized and replaced by RootCmd.PersistentPreRunE for --verbose/--quiet.
+var Log Logger
+
+// Version is the crunchy-cli version, set via -ldflags at build time.
+var Version = "dev"
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProxyRule routes requests to hosts matching Glob (e.g. `*.crunchyroll.com`)
+// through Proxy instead of whatever the default `--proxy` entries resolve
+// to, letting API traffic and CDN traffic take different paths.
+type ProxyRule struct {
+	Glob  string
+	Proxy *url.URL
+}
+
+// ProxyConfig is the resolved state of the `--proxy`/`--proxy-rules`/
+// `--proxy-auth` flags, used to build the RootCmd client's transport.
+type ProxyConfig struct {
+	Proxies []*url.URL
+	Rules   []ProxyRule
+}
+
+// NewProxyConfig parses one or more `--proxy` entries (each prefixed with
+// `http://`, `https://`, `socks5://` or `socks5h://`), an optional
+// `--proxy-rules` file, and an optional `user:pass` to attach to every proxy
+// that doesn't already carry credentials.
+func NewProxyConfig(proxies []string, rulesFile, auth string) (*ProxyConfig, error) {
+	cfg := &ProxyConfig{}
+
+	for _, raw := range proxies {
+		u, err := parseProxyURL(raw, auth)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Proxies = append(cfg.Proxies, u)
+	}
+
+	if rulesFile != "" {
+		rules, err := loadProxyRules(rulesFile, auth)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Rules = rules
+	}
+
+	return cfg, nil
+}
+
+func parseProxyURL(raw, auth string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http(s):// or socks5(h)://", u.Scheme)
+	}
+
+	if auth != "" && u.User == nil {
+		user, pass, ok := strings.Cut(auth, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --proxy-auth %q, expected user:pass", auth)
+		}
+		u.User = url.UserPassword(user, pass)
+	}
+
+	return u, nil
+}
+
+// loadProxyRules reads a `--proxy-rules` file: each non-comment line maps a
+// host glob to a proxy, e.g. `*.akamaized.net socks5://127.0.0.1:1080`.
+func loadProxyRules(path, auth string) ([]ProxyRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening --proxy-rules file: %w", err)
+	}
+	defer f.Close()
+
+	var rules []ProxyRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid --proxy-rules line %q, expected `<glob> <proxy-url>`", line)
+		}
+
+		u, err := parseProxyURL(fields[1], auth)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, ProxyRule{Glob: fields[0], Proxy: u})
+	}
+
+	return rules, scanner.Err()
+}
+
+// For resolves which proxy, if any, should be used for a request to host,
+// preferring the first matching --proxy-rules glob and falling back to the
+// first configured --proxy entry. A host matching one of the comma-separated
+// NO_PROXY entries, compared as a glob like the rules, goes direct; lowercase
+// no_proxy, domain-suffix entries such as `.example.com` and IP ranges are
+// not recognized.
+func (c *ProxyConfig) For(req *http.Request) (*url.URL, error) {
+	if noProxyMatches(req.URL.Hostname()) {
+		return nil, nil
+	}
+
+	for _, rule := range c.Rules {
+		if matchGlob(rule.Glob, req.URL.Hostname()) {
+			return rule.Proxy, nil
+		}
+	}
+
+	if len(c.Proxies) > 0 {
+		return c.Proxies[0], nil
+	}
+
+	return nil, nil
+}
+
+func noProxyMatches(host string) bool {
+	for _, pattern := range strings.Split(os.Getenv("NO_PROXY"), ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern != "" && matchGlob(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(glob, host string) bool {
+	ok, err := filepath.Match(glob, host)
+	return err == nil && ok
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// JobRequest describes a download/archive invocation submitted to a running
+// `crunchy daemon` instead of being executed inline by the calling process.
+// download.Cmd and archive.Cmd populate this from their own flags when
+// `--remote` is set.
+type JobRequest struct {
+	Command string            `json:"command"` // "download" or "archive"
+	URLs    []string          `json:"urls"`
+	Flags   map[string]string `json:"flags"`
+}
+
+// SubmitRemoteJob posts a JobRequest to a running daemon's `POST /jobs`
+// endpoint and returns the id it was assigned. addr is either a full URL or
+// a bare host:port like the daemon's --addr, which is reached over http://.
+func SubmitRemoteJob(addr, apiKey string, job JobRequest) (string, error) {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+
+	base := addr
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/jobs", base), bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("daemon responded with %s", resp.Status)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}